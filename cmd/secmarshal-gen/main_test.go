@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunGeneratesPackedMethods(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "record.go")
+	if err := os.WriteFile(input, []byte(`package demo
+
+// +secmarshal:packed
+type Record struct {
+	ID   uint64
+	Tag  [4]byte
+}
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "record_secmarshal.go")
+	if err := run(input, output); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("reading generated output: %v", err)
+	}
+	for _, want := range []string{"func (v *Record) SizeBytes() int", "func (v *Record) MarshalUnsafe", "func (v *Record) UnmarshalUnsafe", "func (v *Record) Packed() bool"} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("generated output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunRejectsUnpackedFields(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "record.go")
+	if err := os.WriteFile(input, []byte(`package demo
+
+// +secmarshal:packed
+type Record struct {
+	Name string
+}
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(input, filepath.Join(dir, "out.go")); err == nil {
+		t.Fatal("run should reject a struct with a string field")
+	}
+}
+
+func TestRunRequiresAnnotation(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "record.go")
+	if err := os.WriteFile(input, []byte(`package demo
+
+type Record struct {
+	ID uint64
+}
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(input, filepath.Join(dir, "out.go")); err == nil {
+		t.Fatal("run should fail when no struct is annotated")
+	}
+}
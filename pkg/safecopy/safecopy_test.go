@@ -0,0 +1,118 @@
+package safecopy
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+func TestCopyInOutRoundTrip(t *testing.T) {
+	src := []byte("round trip through safecopy")
+	dst := make([]byte, len(src))
+
+	n, err := CopyOut(unsafe.Pointer(&dst[0]), src)
+	if err != nil {
+		t.Fatalf("CopyOut: %v", err)
+	}
+	if n != len(src) {
+		t.Fatalf("CopyOut returned %d, want %d", n, len(src))
+	}
+	if !bytes.Equal(dst, src) {
+		t.Fatalf("dst = %q, want %q", dst, src)
+	}
+
+	back := make([]byte, len(src))
+	if _, err := CopyIn(back, unsafe.Pointer(&dst[0])); err != nil {
+		t.Fatalf("CopyIn: %v", err)
+	}
+	if !bytes.Equal(back, src) {
+		t.Fatalf("back = %q, want %q", back, src)
+	}
+}
+
+func TestZero(t *testing.T) {
+	data := []byte("not zero yet")
+	if err := Zero(unsafe.Pointer(&data[0]), len(data)); err != nil {
+		t.Fatalf("Zero: %v", err)
+	}
+	for i, b := range data {
+		if b != 0 {
+			t.Fatalf("byte %d = %#x after Zero, want 0", i, b)
+		}
+	}
+}
+
+func mmapGuardPage(t *testing.T) []byte {
+	t.Helper()
+	data, err := syscall.Mmap(-1, 0, syscall.Getpagesize(),
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		t.Fatalf("mmap: %v", err)
+	}
+	if err := syscall.Mprotect(data, syscall.PROT_NONE); err != nil {
+		t.Fatalf("mprotect: %v", err)
+	}
+	t.Cleanup(func() {
+		syscall.Mprotect(data, syscall.PROT_READ|syscall.PROT_WRITE)
+		syscall.Munmap(data)
+	})
+	return data
+}
+
+func TestCopyInFaultsCleanly(t *testing.T) {
+	guard := mmapGuardPage(t)
+	dst := make([]byte, len(guard))
+
+	_, err := CopyIn(dst, unsafe.Pointer(&guard[0]))
+	if err == nil {
+		t.Fatal("CopyIn from a PROT_NONE page should have faulted")
+	}
+	segv, ok := err.(*SegvError)
+	if !ok {
+		t.Fatalf("err = %T(%v), want *SegvError", err, err)
+	}
+	if segv.Addr == 0 {
+		t.Fatal("SegvError.Addr should not be zero")
+	}
+}
+
+func TestCopyOutFaultsCleanly(t *testing.T) {
+	guard := mmapGuardPage(t)
+	src := make([]byte, len(guard))
+
+	_, err := CopyOut(unsafe.Pointer(&guard[0]), src)
+	if err == nil {
+		t.Fatal("CopyOut to a PROT_NONE page should have faulted")
+	}
+	if _, ok := err.(*SegvError); !ok {
+		t.Fatalf("err = %T(%v), want *SegvError", err, err)
+	}
+}
+
+func TestZeroFaultsCleanly(t *testing.T) {
+	guard := mmapGuardPage(t)
+
+	err := Zero(unsafe.Pointer(&guard[0]), len(guard))
+	if err == nil {
+		t.Fatal("Zero of a PROT_NONE page should have faulted")
+	}
+	if _, ok := err.(*SegvError); !ok {
+		t.Fatalf("err = %T(%v), want *SegvError", err, err)
+	}
+}
+
+func TestGoroutineSurvivesRepeatedFaults(t *testing.T) {
+	guard := mmapGuardPage(t)
+	dst := make([]byte, len(guard))
+	for i := 0; i < 100; i++ {
+		if _, err := CopyIn(dst, unsafe.Pointer(&guard[0])); err == nil {
+			t.Fatalf("iteration %d: expected fault", i)
+		}
+	}
+	// The goroutine, and SetPanicOnFault's state, must still be usable
+	// for ordinary (non-faulting) copies afterwards.
+	if _, err := CopyIn(dst, unsafe.Pointer(&dst[0])); err != nil {
+		t.Fatalf("CopyIn on valid memory after faults: %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+// Package example demonstrates a struct annotated for cmd/secmarshal-gen.
+package example
+
+//go:generate go run ../../../cmd/secmarshal-gen -input header.go
+
+// Header is a fixed-size record safe to place directly into locked memory
+// via securemem.Buffer.PutAt/GetAt.
+//
+// +secmarshal:packed
+type Header struct {
+	Version uint32
+	Flags   uint32
+	Nonce   [24]byte
+}
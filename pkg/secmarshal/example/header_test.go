@@ -0,0 +1,47 @@
+package example
+
+import (
+	"testing"
+
+	"github.com/ursa-mikail/mem_safe_copy_transfer/pkg/secmarshal"
+	"github.com/ursa-mikail/mem_safe_copy_transfer/pkg/securemem"
+)
+
+var _ secmarshal.Marshallable = (*Header)(nil)
+
+func TestHeaderRoundTripThroughBuffer(t *testing.T) {
+	buf, err := securemem.New(64)
+	if err != nil {
+		t.Fatalf("securemem.New: %v", err)
+	}
+	defer buf.Close()
+
+	want := &Header{Version: 3, Flags: 0xdeadbeef}
+	copy(want.Nonce[:], "0123456789abcdef01234567")
+
+	if err := buf.PutAt(8, want); err != nil {
+		t.Fatalf("PutAt: %v", err)
+	}
+
+	got := &Header{}
+	if err := buf.GetAt(8, got); err != nil {
+		t.Fatalf("GetAt: %v", err)
+	}
+
+	if *got != *want {
+		t.Fatalf("GetAt = %+v, want %+v", got, want)
+	}
+}
+
+func TestHeaderPutAtOutOfRange(t *testing.T) {
+	buf, err := securemem.New(16)
+	if err != nil {
+		t.Fatalf("securemem.New: %v", err)
+	}
+	defer buf.Close()
+
+	h := &Header{}
+	if err := buf.PutAt(4, h); err == nil {
+		t.Fatal("PutAt at an offset that overruns the buffer should fail")
+	}
+}
@@ -0,0 +1,33 @@
+// Package secmarshal lets typed structs be placed directly into locked
+// memory (see pkg/securemem) instead of forcing callers to hand-roll byte
+// encodings for keys, credentials, and headers.
+package secmarshal
+
+// Marshallable is implemented by types that can be read from and written
+// to a locked memory region in place.
+//
+// Implementations where Packed reports true are expected to marshal via a
+// single reinterpret-and-copy of their own memory (no field-by-field
+// encoding), which is what cmd/secmarshal-gen generates for annotated
+// structs. Implementations where Packed reports false should fall back to
+// encoding field by field; securemem.Buffer.PutAt and GetAt don't care
+// either way, but callers deciding whether a type is safe to memmove
+// across architectures (alignment, endianness) should check it.
+type Marshallable interface {
+	// SizeBytes returns the number of bytes MarshalUnsafe writes and
+	// UnmarshalUnsafe reads.
+	SizeBytes() int
+
+	// MarshalUnsafe writes the receiver's encoding into dst, which must
+	// be at least SizeBytes() long.
+	MarshalUnsafe(dst []byte)
+
+	// UnmarshalUnsafe populates the receiver from src, which must be at
+	// least SizeBytes() long.
+	UnmarshalUnsafe(src []byte)
+
+	// Packed reports whether the type's in-memory layout is its wire
+	// encoding, i.e. whether Marshal/UnmarshalUnsafe are a plain memmove
+	// rather than a field-by-field encoding.
+	Packed() bool
+}
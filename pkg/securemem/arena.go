@@ -0,0 +1,277 @@
+package securemem
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// defaultMaxLocked is the total size of an Arena's backing region when
+// WithMaxLocked is not given.
+const defaultMaxLocked = 1 << 20 // 1 MiB
+
+// arenaClassSizes are the power-of-two slot sizes an Arena divides its
+// locked region into. Alloc rounds a request up to the smallest class
+// that fits it.
+var arenaClassSizes = []int{16, 32, 64, 128, 256, 512, 1024, 2048, 4096, 8192, 16384, 32768, 65536}
+
+// reshuffleEvery is how many releases a size class tolerates before its
+// free list is reshuffled from scratch, so a long-running process with a
+// steady alloc/free rhythm doesn't settle into a predictable slot order.
+const reshuffleEvery = 64
+
+type arenaConfig struct {
+	maxLocked int
+}
+
+// ArenaOption configures NewArena.
+type ArenaOption func(*arenaConfig)
+
+// WithMaxLocked caps the total bytes an Arena locks into physical memory,
+// so callers can pre-size an Arena against RLIMIT_MEMLOCK instead of
+// discovering the limit from a failed mlock. NewArena never locks more
+// than bytes: a budget smaller than the smallest size class is rejected
+// rather than rounded up.
+func WithMaxLocked(bytes int) ArenaOption {
+	return func(c *arenaConfig) { c.maxLocked = bytes }
+}
+
+type arenaClass struct {
+	size      int
+	offset    int // start of this class's slots within Arena.region
+	slotCount int
+	used      []bool
+	freeList  []int
+
+	sinceShuffle int
+}
+
+// Arena is a single mmap+mlock region, mapped once and pre-divided into
+// power-of-two size classes, that hands out Buffers from randomly chosen
+// slots instead of mapping and locking memory per allocation. Which free
+// slot within a class is handed out is chosen via crypto/rand, so
+// repeated Alloc/Close cycles for the same size do not return the same
+// address. Buffers from Alloc are released the same way as any other
+// Buffer, via Close: Close wipes the slot (Method 2 semantics -- clear
+// once, only the memory actually touched) and returns it to the Arena's
+// free list instead of unmapping it.
+type Arena struct {
+	mu      sync.Mutex
+	region  []byte
+	classes []arenaClass
+}
+
+// NewArena allocates and locks an Arena's backing region up front,
+// divided into size classes. By default the region is defaultMaxLocked
+// bytes; use WithMaxLocked to change that.
+func NewArena(opts ...ArenaOption) (*Arena, error) {
+	cfg := arenaConfig{maxLocked: defaultMaxLocked}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.maxLocked <= 0 {
+		return nil, fmt.Errorf("securemem: invalid arena size %d", cfg.maxLocked)
+	}
+	if cfg.maxLocked < arenaClassSizes[0] {
+		return nil, fmt.Errorf("securemem: arena budget %d is smaller than the smallest size class (%d)", cfg.maxLocked, arenaClassSizes[0])
+	}
+
+	var sizes []int
+	for _, s := range arenaClassSizes {
+		if s <= cfg.maxLocked {
+			sizes = append(sizes, s)
+		}
+	}
+	if len(sizes) == 0 {
+		sizes = arenaClassSizes[:1]
+	}
+
+	// Split the budget evenly across classes, then drop any class whose
+	// fair share can't afford even one slot and re-split, until every
+	// surviving class gets at least one slot without exceeding maxLocked.
+	perClassBudget := cfg.maxLocked / len(sizes)
+	for {
+		var fits []int
+		for _, s := range sizes {
+			if s <= perClassBudget {
+				fits = append(fits, s)
+			}
+		}
+		if len(fits) == len(sizes) {
+			break
+		}
+		if len(fits) == 0 {
+			fits = sizes[:1]
+			perClassBudget = cfg.maxLocked
+			sizes = fits
+			break
+		}
+		sizes = fits
+		perClassBudget = cfg.maxLocked / len(sizes)
+	}
+
+	classes := make([]arenaClass, len(sizes))
+	total := 0
+	for i, size := range sizes {
+		slotCount := perClassBudget / size
+		if slotCount < 1 {
+			slotCount = 1
+		}
+		c := arenaClass{
+			size:      size,
+			offset:    total,
+			slotCount: slotCount,
+			used:      make([]bool, slotCount),
+			freeList:  make([]int, slotCount),
+		}
+		for s := range c.freeList {
+			c.freeList[s] = s
+		}
+		classes[i] = c
+		total += size * slotCount
+	}
+
+	region, err := allocLocked(total)
+	if err != nil {
+		return nil, err
+	}
+	return &Arena{region: region, classes: classes}, nil
+}
+
+// Alloc hands out a Buffer of the given size, backed by a randomly chosen
+// free slot in the smallest size class that fits it. It returns
+// ErrRequestTooLarge if size exceeds the Arena's biggest class, or
+// ErrArenaExhausted if that class has no free slots.
+func (a *Arena) Alloc(size int) (*Buffer, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("securemem: invalid size %d", size)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	classIdx := -1
+	for i := range a.classes {
+		if a.classes[i].size >= size {
+			classIdx = i
+			break
+		}
+	}
+	if classIdx == -1 {
+		return nil, ErrRequestTooLarge
+	}
+
+	class := &a.classes[classIdx]
+	if len(class.freeList) == 0 {
+		return nil, ErrArenaExhausted
+	}
+
+	i, err := randIndex(len(class.freeList))
+	if err != nil {
+		return nil, err
+	}
+	slot := class.freeList[i]
+	class.freeList[i] = class.freeList[len(class.freeList)-1]
+	class.freeList = class.freeList[:len(class.freeList)-1]
+	class.used[slot] = true
+
+	off := class.offset + slot*class.size
+	slotRegion := a.region[off : off+class.size]
+
+	return &Buffer{
+		data:       slotRegion[:size],
+		lock:       slotRegion,
+		arena:      a,
+		arenaClass: classIdx,
+		arenaSlot:  slot,
+	}, nil
+}
+
+// release returns slot in the given class to the free list. Buffer.Close
+// calls it, after it has already wiped the slot, for any Buffer obtained
+// from Alloc.
+func (a *Arena) release(classIdx, slot int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	class := &a.classes[classIdx]
+	class.used[slot] = false
+	class.freeList = append(class.freeList, slot)
+
+	class.sinceShuffle++
+	if class.sinceShuffle >= reshuffleEvery {
+		if err := shuffle(class.freeList); err != nil {
+			return err
+		}
+		class.sinceShuffle = 0
+	}
+	return nil
+}
+
+// ClassStats reports one size class's utilization.
+type ClassStats struct {
+	Size      int
+	SlotCount int
+	Free      int
+}
+
+// ArenaStats reports an Arena's overall utilization.
+type ArenaStats struct {
+	TotalBytes int
+	UsedBytes  int
+	Classes    []ClassStats
+}
+
+// Stats reports the Arena's current utilization, broken down by size
+// class.
+func (a *Arena) Stats() ArenaStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats := ArenaStats{
+		TotalBytes: len(a.region),
+		Classes:    make([]ClassStats, len(a.classes)),
+	}
+	for i, c := range a.classes {
+		free := len(c.freeList)
+		stats.Classes[i] = ClassStats{Size: c.size, SlotCount: c.slotCount, Free: free}
+		stats.UsedBytes += (c.slotCount - free) * c.size
+	}
+	return stats
+}
+
+// Close unlocks and releases the Arena's entire backing region. Any
+// Buffer still outstanding from Alloc becomes invalid; callers must Close
+// every outstanding Buffer first. Close is idempotent.
+func (a *Arena) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.region == nil {
+		return nil
+	}
+	err := releaseLocked(a.region)
+	a.region = nil
+	return err
+}
+
+// randIndex returns a uniformly random integer in [0, n) via crypto/rand.
+func randIndex(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("securemem: generating random arena slot: %w", err)
+	}
+	return int(v.Int64()), nil
+}
+
+// shuffle performs an in-place Fisher-Yates shuffle of s via crypto/rand.
+func shuffle(s []int) error {
+	for i := len(s) - 1; i > 0; i-- {
+		j, err := randIndex(i + 1)
+		if err != nil {
+			return err
+		}
+		s[i], s[j] = s[j], s[i]
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package securemem
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestArenaAllocAndClose(t *testing.T) {
+	a, err := NewArena(WithMaxLocked(64 * 1024))
+	if err != nil {
+		t.Fatalf("NewArena: %v", err)
+	}
+	defer a.Close()
+
+	buf, err := a.Alloc(40)
+	if err != nil {
+		t.Fatalf("Alloc: %v", err)
+	}
+	if len(buf.Bytes()) != 40 {
+		t.Fatalf("len(Bytes()) = %d, want 40", len(buf.Bytes()))
+	}
+	copy(buf.Bytes(), []byte("hello, arena"))
+
+	if err := buf.Close(); err != nil {
+		t.Fatalf("buf.Close: %v", err)
+	}
+}
+
+func TestArenaSlotIsWipedOnClose(t *testing.T) {
+	// A 96-byte budget leaves the 32-byte class a single slot, so the
+	// second Alloc below is handed the very slot the first Close wiped.
+	a, err := NewArena(WithMaxLocked(96))
+	if err != nil {
+		t.Fatalf("NewArena: %v", err)
+	}
+	defer a.Close()
+
+	buf, err := a.Alloc(24)
+	if err != nil {
+		t.Fatalf("Alloc: %v", err)
+	}
+	copy(buf.Bytes(), []byte("secret material to wipe"))
+	if err := buf.Close(); err != nil {
+		t.Fatalf("buf.Close: %v", err)
+	}
+
+	buf2, err := a.Alloc(24)
+	if err != nil {
+		t.Fatalf("Alloc (reuse): %v", err)
+	}
+	defer buf2.Close()
+	for i, c := range buf2.Bytes() {
+		if c != 0 {
+			t.Fatalf("reused slot byte %d = %#x, want 0 (not wiped on prior Close)", i, c)
+		}
+	}
+}
+
+func TestArenaExhaustion(t *testing.T) {
+	a, err := NewArena(WithMaxLocked(4 * 1024))
+	if err != nil {
+		t.Fatalf("NewArena: %v", err)
+	}
+	defer a.Close()
+
+	stats := a.Stats()
+	var smallest ClassStats
+	for _, c := range stats.Classes {
+		if smallest.Size == 0 || c.Size < smallest.Size {
+			smallest = c
+		}
+	}
+
+	var bufs []*Buffer
+	for i := 0; i < smallest.SlotCount; i++ {
+		buf, err := a.Alloc(smallest.Size)
+		if err != nil {
+			t.Fatalf("Alloc #%d: %v", i, err)
+		}
+		bufs = append(bufs, buf)
+	}
+	defer func() {
+		for _, buf := range bufs {
+			buf.Close()
+		}
+	}()
+
+	if _, err := a.Alloc(smallest.Size); !errors.Is(err, ErrArenaExhausted) {
+		t.Fatalf("Alloc past capacity: got %v, want ErrArenaExhausted", err)
+	}
+}
+
+func TestArenaRequestTooLarge(t *testing.T) {
+	a, err := NewArena(WithMaxLocked(4 * 1024))
+	if err != nil {
+		t.Fatalf("NewArena: %v", err)
+	}
+	defer a.Close()
+
+	if _, err := a.Alloc(1 << 30); !errors.Is(err, ErrRequestTooLarge) {
+		t.Fatalf("Alloc(huge): got %v, want ErrRequestTooLarge", err)
+	}
+}
+
+func TestArenaBudgetTooSmall(t *testing.T) {
+	if _, err := NewArena(WithMaxLocked(1)); err == nil {
+		t.Fatalf("NewArena(1 byte): got nil error, want one rejecting a budget smaller than the smallest size class")
+	}
+	if _, err := NewArena(WithMaxLocked(arenaClassSizes[0] - 1)); err == nil {
+		t.Fatalf("NewArena(%d bytes): got nil error, want one rejecting a budget smaller than the smallest size class", arenaClassSizes[0]-1)
+	}
+	a, err := NewArena(WithMaxLocked(arenaClassSizes[0]))
+	if err != nil {
+		t.Fatalf("NewArena(%d bytes): %v", arenaClassSizes[0], err)
+	}
+	a.Close()
+}
+
+func TestArenaStats(t *testing.T) {
+	a, err := NewArena(WithMaxLocked(64 * 1024))
+	if err != nil {
+		t.Fatalf("NewArena: %v", err)
+	}
+	defer a.Close()
+
+	before := a.Stats()
+	if before.UsedBytes != 0 {
+		t.Fatalf("UsedBytes = %d before any Alloc, want 0", before.UsedBytes)
+	}
+
+	buf, err := a.Alloc(100)
+	if err != nil {
+		t.Fatalf("Alloc: %v", err)
+	}
+	defer buf.Close()
+
+	after := a.Stats()
+	if after.UsedBytes == 0 {
+		t.Fatalf("UsedBytes = 0 after Alloc, want > 0")
+	}
+}
@@ -0,0 +1,171 @@
+// Package securemem provides a page-locked Buffer type for holding secret
+// bytes in memory that is never paged to swap and, where the platform
+// supports it, excluded from core dumps and child processes.
+//
+// A Buffer must be released with Close once it is no longer needed; Close
+// wipes the contents before unmapping them.
+package securemem
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/ursa-mikail/mem_safe_copy_transfer/pkg/safecopy"
+)
+
+// Buffer is a fixed-size region of locked memory.
+type Buffer struct {
+	data   []byte // the caller-visible region, len(data) == the requested size
+	lock   []byte // the region that was actually mlock'd (data, plus any canary)
+	region []byte // the full mmap'd region, including guard pages if any
+
+	guarded bool
+
+	hasCanary  bool
+	canaryOff  int
+	canaryWant [8]byte
+
+	// arena, arenaClass and arenaSlot are set for Buffers handed out by
+	// Arena.Alloc. Close routes to arena.release instead of unmapping
+	// the (shared) backing region.
+	arena      *Arena
+	arenaClass int
+	arenaSlot  int
+
+	closed bool
+}
+
+// New allocates a Buffer of the given size and locks it into physical
+// memory. The backing memory is zeroed on return. New has no guard pages
+// or canary; use NewGuarded for overflow detection.
+func New(size int) (*Buffer, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("securemem: invalid size %d", size)
+	}
+	data, err := allocLocked(size)
+	if err != nil {
+		return nil, err
+	}
+	return &Buffer{data: data, lock: data, region: data}, nil
+}
+
+// Bytes returns the buffer's backing slice. The slice is only valid until
+// Close is called; callers must not retain it past that point.
+func (b *Buffer) Bytes() []byte {
+	if b.closed {
+		return nil
+	}
+	return b.data
+}
+
+// Copy copies len(src.Bytes()) bytes from src into dst, returning the
+// number of bytes copied. dst and src must be the same size, and neither
+// may be closed.
+//
+// The copy goes through pkg/safecopy rather than Go's builtin copy, so a
+// Buffer whose backing mapping has become invalid (a guard-page hit, a
+// mapping that the OS has since revoked) returns an error instead of
+// crashing the process. This does not cover the Go-level data race of
+// calling Copy concurrently with Close on the same Buffer: Buffer's
+// fields are unsynchronized, so that is a caller bug, not something
+// safecopy can catch. Buffer is not safe for concurrent use; callers
+// sharing a Buffer across goroutines must provide their own
+// synchronization.
+//
+// If SetPointerCheck is enabled, Copy also scans the bytes it just wrote
+// (and, at PointerCheckFull, dst's whole locked region) for values that
+// look like live Go pointers, returning ErrGoPointerLeak.
+func Copy(dst, src *Buffer) (int, error) {
+	if dst == nil || src == nil {
+		return 0, fmt.Errorf("securemem: Copy called with nil buffer")
+	}
+	if dst.closed || src.closed {
+		return 0, ErrClosed
+	}
+	if len(dst.data) != len(src.data) {
+		return 0, ErrSizeMismatch
+	}
+	if len(dst.data) == 0 {
+		return 0, nil
+	}
+	n, err := safecopy.CopyIn(dst.data, unsafe.Pointer(&src.data[0]))
+	if err != nil {
+		return n, err
+	}
+	if err := checkPointerWrite(dst, dst.data[:n]); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+// Wipe overwrites the buffer's contents with zeroes. It is safe to call
+// Wipe more than once, and on a closed Buffer (where it is a no-op).
+//
+// Wipe goes through pkg/safecopy so that zeroing a Buffer whose mapping
+// has become invalid returns an error instead of crashing the process.
+// A canary word (see GuardCanary) is left in place, so a later
+// VerifyCanary or Close does not report the wipe as an overflow.
+func (b *Buffer) Wipe() error {
+	if b.closed || len(b.lock) == 0 {
+		return nil
+	}
+	if !b.hasCanary {
+		return safecopy.Zero(unsafe.Pointer(&b.lock[0]), len(b.lock))
+	}
+	if err := safecopy.Zero(unsafe.Pointer(&b.lock[0]), b.canaryOff); err != nil {
+		return err
+	}
+	if tail := b.canaryOff + 8; tail < len(b.lock) {
+		return safecopy.Zero(unsafe.Pointer(&b.lock[tail]), len(b.lock)-tail)
+	}
+	return nil
+}
+
+// Close wipes the buffer and releases its memory: back to the OS for a
+// Buffer from New or NewGuarded, or back to the owning Arena's free list
+// for a Buffer from Arena.Alloc. If the buffer was created with NewGuarded
+// and a canary, Close verifies the canary first and reports
+// ErrCanaryCorrupted (without skipping the wipe or release) if it was
+// overwritten. Close is idempotent.
+func (b *Buffer) Close() error {
+	if b.closed {
+		return nil
+	}
+
+	var canaryErr error
+	if b.hasCanary {
+		canaryErr = b.VerifyCanary()
+	}
+
+	wipeErr := b.Wipe()
+
+	var relErr error
+	switch {
+	case b.arena != nil:
+		relErr = b.arena.release(b.arenaClass, b.arenaSlot)
+	case b.guarded:
+		unlockErr := unlockRegion(b.lock)
+		munmapErr := rawMunmap(b.region)
+		if unlockErr != nil {
+			relErr = unlockErr
+		} else {
+			relErr = munmapErr
+		}
+	default:
+		relErr = releaseLocked(b.lock)
+	}
+
+	b.data = nil
+	b.lock = nil
+	b.region = nil
+	b.arena = nil
+	b.closed = true
+
+	if canaryErr != nil {
+		return canaryErr
+	}
+	if wipeErr != nil {
+		return wipeErr
+	}
+	return relErr
+}
@@ -0,0 +1,112 @@
+//go:build darwin
+
+package securemem
+
+/*
+#include <sys/mman.h>
+#include <errno.h>
+
+// minherit(2) is not wrapped by the syscall package and, on arm64, is not
+// reachable through a raw syscall trap, so we go through libSystem via cgo.
+static int securemem_minherit(void *addr, size_t len) {
+	return minherit(addr, len, VM_INHERIT_NONE);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+func pageSize() int { return syscall.Getpagesize() }
+
+// rawMmap reserves size bytes of anonymous, page-aligned memory without
+// locking or protecting it.
+func rawMmap(size int) ([]byte, error) {
+	data, err := syscall.Mmap(-1, 0, size,
+		syscall.PROT_READ|syscall.PROT_WRITE,
+		syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, fmt.Errorf("securemem: mmap: %w", err)
+	}
+	return data, nil
+}
+
+func rawMunmap(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := syscall.Munmap(data); err != nil {
+		return fmt.Errorf("securemem: munmap: %w", err)
+	}
+	return nil
+}
+
+// protectNone makes b inaccessible; any read or write to it faults.
+func protectNone(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	if err := syscall.Mprotect(b, syscall.PROT_NONE); err != nil {
+		return fmt.Errorf("securemem: mprotect(PROT_NONE): %w", err)
+	}
+	return nil
+}
+
+func lockRegion(b []byte) error {
+	if err := syscall.Mlock(b); err != nil {
+		if err == syscall.ENOMEM || err == syscall.EPERM {
+			return &MemlockLimitError{Requested: len(b), Err: err}
+		}
+		return fmt.Errorf("securemem: mlock: %w", err)
+	}
+	return nil
+}
+
+func unlockRegion(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	if err := syscall.Munlock(b); err != nil {
+		return fmt.Errorf("securemem: munlock: %w", err)
+	}
+	return nil
+}
+
+// hardenRegion marks b VM_INHERIT_NONE so it is unmapped, rather than
+// copied, into forked children. Best effort: a failure here doesn't make b
+// unsafe to use, just less hardened against fork().
+func hardenRegion(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	C.securemem_minherit(unsafe.Pointer(&b[0]), C.size_t(len(b)))
+}
+
+// allocLocked maps, locks and hardens a region with no guard pages.
+func allocLocked(size int) ([]byte, error) {
+	data, err := rawMmap(size)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockRegion(data); err != nil {
+		rawMunmap(data)
+		return nil, err
+	}
+	hardenRegion(data)
+	return data, nil
+}
+
+func releaseLocked(data []byte) error {
+	if data == nil {
+		return nil
+	}
+	unlockErr := unlockRegion(data)
+	munmapErr := rawMunmap(data)
+	if unlockErr != nil {
+		return unlockErr
+	}
+	return munmapErr
+}
@@ -0,0 +1,106 @@
+//go:build linux
+
+package securemem
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// madvDontDump, madvWipeOnFork are MADV_DONTDUMP and MADV_WIPEONFORK. The
+// syscall package only defines them on a handful of GOARCHes, so they are
+// hard-coded here; the values are stable across all Linux architectures.
+const (
+	madvDontDump   = 0x10
+	madvWipeOnFork = 0x12
+)
+
+func pageSize() int { return syscall.Getpagesize() }
+
+// rawMmap reserves size bytes of anonymous, page-aligned memory without
+// locking or protecting it.
+func rawMmap(size int) ([]byte, error) {
+	data, err := syscall.Mmap(-1, 0, size,
+		syscall.PROT_READ|syscall.PROT_WRITE,
+		syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, fmt.Errorf("securemem: mmap: %w", err)
+	}
+	return data, nil
+}
+
+func rawMunmap(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := syscall.Munmap(data); err != nil {
+		return fmt.Errorf("securemem: munmap: %w", err)
+	}
+	return nil
+}
+
+// protectNone makes b inaccessible; any read or write to it faults.
+func protectNone(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	if err := syscall.Mprotect(b, syscall.PROT_NONE); err != nil {
+		return fmt.Errorf("securemem: mprotect(PROT_NONE): %w", err)
+	}
+	return nil
+}
+
+func lockRegion(b []byte) error {
+	if err := syscall.Mlock(b); err != nil {
+		if err == syscall.ENOMEM || err == syscall.EPERM {
+			return &MemlockLimitError{Requested: len(b), Err: err}
+		}
+		return fmt.Errorf("securemem: mlock: %w", err)
+	}
+	return nil
+}
+
+func unlockRegion(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	if err := syscall.Munlock(b); err != nil {
+		return fmt.Errorf("securemem: munlock: %w", err)
+	}
+	return nil
+}
+
+// hardenRegion advises the kernel to keep b out of core dumps, away from
+// forked children, and wiped (rather than shared) across fork. Best
+// effort: a failure here doesn't make b unsafe to use, just less hardened.
+func hardenRegion(b []byte) {
+	syscall.Madvise(b, madvDontDump)
+	syscall.Madvise(b, syscall.MADV_DONTFORK)
+	syscall.Madvise(b, madvWipeOnFork)
+}
+
+// allocLocked maps, locks and hardens a region with no guard pages.
+func allocLocked(size int) ([]byte, error) {
+	data, err := rawMmap(size)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockRegion(data); err != nil {
+		rawMunmap(data)
+		return nil, err
+	}
+	hardenRegion(data)
+	return data, nil
+}
+
+func releaseLocked(data []byte) error {
+	if data == nil {
+		return nil
+	}
+	unlockErr := unlockRegion(data)
+	munmapErr := rawMunmap(data)
+	if unlockErr != nil {
+		return unlockErr
+	}
+	return munmapErr
+}
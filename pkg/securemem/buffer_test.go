@@ -0,0 +1,92 @@
+package securemem
+
+import "testing"
+
+func TestNewAndClose(t *testing.T) {
+	buf, err := New(4096)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if len(buf.Bytes()) != 4096 {
+		t.Fatalf("Bytes() length = %d, want 4096", len(buf.Bytes()))
+	}
+	if err := buf.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if buf.Bytes() != nil {
+		t.Fatalf("Bytes() after Close = %v, want nil", buf.Bytes())
+	}
+	if err := buf.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestNewInvalidSize(t *testing.T) {
+	if _, err := New(0); err == nil {
+		t.Fatal("New(0) should have failed")
+	}
+	if _, err := New(-1); err == nil {
+		t.Fatal("New(-1) should have failed")
+	}
+}
+
+func TestCopy(t *testing.T) {
+	src, err := New(32)
+	if err != nil {
+		t.Fatalf("New(src): %v", err)
+	}
+	defer src.Close()
+	dst, err := New(32)
+	if err != nil {
+		t.Fatalf("New(dst): %v", err)
+	}
+	defer dst.Close()
+
+	copy(src.Bytes(), []byte("top secret material"))
+
+	n, err := Copy(dst, src)
+	if err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if n != 32 {
+		t.Fatalf("Copy returned %d, want 32", n)
+	}
+	if string(dst.Bytes()[:19]) != "top secret material" {
+		t.Fatalf("dst = %q, want copy of src", dst.Bytes()[:19])
+	}
+}
+
+func TestCopySizeMismatch(t *testing.T) {
+	small, err := New(16)
+	if err != nil {
+		t.Fatalf("New(small): %v", err)
+	}
+	defer small.Close()
+	big, err := New(32)
+	if err != nil {
+		t.Fatalf("New(big): %v", err)
+	}
+	defer big.Close()
+
+	if _, err := Copy(big, small); err != ErrSizeMismatch {
+		t.Fatalf("Copy(big, small) err = %v, want ErrSizeMismatch", err)
+	}
+}
+
+func TestWipe(t *testing.T) {
+	buf, err := New(16)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer buf.Close()
+
+	copy(buf.Bytes(), []byte("0123456789abcdef"))
+	if err := buf.Wipe(); err != nil {
+		t.Fatalf("Wipe: %v", err)
+	}
+	for i, b := range buf.Bytes() {
+		if b != 0 {
+			t.Fatalf("byte %d = %#x after Wipe, want 0", i, b)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package securemem
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrClosed is returned when an operation is attempted on a Buffer that has
+// already been closed.
+var ErrClosed = errors.New("securemem: buffer is closed")
+
+// ErrSizeMismatch is returned by Copy when the source and destination
+// buffers are not the same size.
+var ErrSizeMismatch = errors.New("securemem: source and destination buffers differ in size")
+
+// ErrNotSupported is returned by platform hooks (e.g. Lockall) that have no
+// meaningful implementation on the current GOOS.
+var ErrNotSupported = errors.New("securemem: not supported on this platform")
+
+// ErrArenaExhausted is returned by Arena.Alloc when every slot in the size
+// class that fits the request is currently in use.
+var ErrArenaExhausted = errors.New("securemem: arena size class exhausted")
+
+// ErrRequestTooLarge is returned by Arena.Alloc when size is bigger than
+// the Arena's largest size class.
+var ErrRequestTooLarge = errors.New("securemem: requested size larger than arena's biggest size class")
+
+// MemlockLimitError is returned when the kernel refuses to lock memory
+// because the calling process has exhausted RLIMIT_MEMLOCK. Callers can
+// raise the limit (via setrlimit, or by running as a privileged user) and
+// retry New with the same size.
+type MemlockLimitError struct {
+	Requested int
+	Err       error
+}
+
+func (e *MemlockLimitError) Error() string {
+	return fmt.Sprintf("securemem: failed to lock %d bytes, RLIMIT_MEMLOCK too low (raise it with setrlimit and retry): %v", e.Requested, e.Err)
+}
+
+func (e *MemlockLimitError) Unwrap() error { return e.Err }
@@ -0,0 +1,35 @@
+package securemem
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// secretSliceEscape matches a `go build -gcflags=-m` line reporting that
+// one of the slices holding actual secret bytes (as opposed to, say, an
+// int or error value boxed for fmt.Errorf) escapes to the heap. Buffer
+// methods pass these slices to pkg/safecopy and pkg/wipe by unsafe
+// pointer specifically so the underlying bytes are never copied to a
+// heap-allocated []byte; this test locks that in so a future refactor
+// can't silently reintroduce a heap copy of secret data.
+var secretSliceEscape = regexp.MustCompile(`\b(data|lock|region|dst|src)\b escapes to heap`)
+
+func TestBufferSecretSlicesDoNotEscape(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found in PATH")
+	}
+
+	out, err := exec.Command(goBin, "build", "-gcflags=-m", ".").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go build -gcflags=-m: %v\n%s", err, out)
+	}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		if secretSliceEscape.MatchString(line) {
+			t.Errorf("secret slice escapes to heap: %s", line)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package securemem
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// ErrCanaryCorrupted is returned by VerifyCanary (and by Close, for buffers
+// created with canary checking) when the canary word no longer matches the
+// value written at allocation time, indicating the buffer was overflowed.
+var ErrCanaryCorrupted = errors.New("securemem: canary corrupted, buffer was overflowed")
+
+// GuardMode selects which overflow defenses NewGuarded installs.
+type GuardMode int
+
+const (
+	// GuardHard surrounds the buffer with PROT_NONE guard pages, so a
+	// linear over/underflow faults the process immediately.
+	GuardHard GuardMode = 1 << iota
+	// GuardCanary places a random word immediately after the usable
+	// region and checks it in VerifyCanary / Close.
+	GuardCanary
+)
+
+// GuardBoth enables both guard pages and a canary.
+const GuardBoth = GuardHard | GuardCanary
+
+type guardConfig struct {
+	mode      GuardMode
+	finalizer bool
+}
+
+// Option configures NewGuarded.
+type Option func(*guardConfig)
+
+// WithGuardMode selects which defenses to install. The default, if no
+// Option sets a mode, is GuardBoth.
+func WithGuardMode(mode GuardMode) Option {
+	return func(c *guardConfig) { c.mode = mode }
+}
+
+// WithFinalizer registers a runtime.SetFinalizer that calls Close if the
+// Buffer is garbage collected without one. It is opt-in: finalizers run at
+// an unpredictable time (or not at all before process exit), so callers
+// should still Close explicitly; this is a backstop against leaks.
+func WithFinalizer() Option {
+	return func(c *guardConfig) { c.finalizer = true }
+}
+
+// NewGuarded allocates a Buffer of the given size with guard pages and/or
+// a canary around it, per opts. With GuardHard, the returned region is
+// flanked by a PROT_NONE page on each side, so the OS will have already
+// mapped pageSize + roundUp(size) + pageSize bytes (size plus the 8-byte
+// canary, if any, before rounding). A write that runs off the end of that
+// rounded-up region into the tail guard page faults; a write that stays
+// between the end of Bytes() and the page boundary does not fault (with
+// GuardCanary, one that reaches the canary is reported by VerifyCanary).
+func NewGuarded(size int, opts ...Option) (*Buffer, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("securemem: invalid size %d", size)
+	}
+
+	cfg := guardConfig{mode: GuardBoth}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	ps := pageSize()
+	innerSize := size
+	if cfg.mode&GuardCanary != 0 {
+		innerSize += 8
+	}
+	innerSize = roundUp(innerSize, ps)
+
+	var region, lock []byte
+	var err error
+	guarded := cfg.mode&GuardHard != 0
+	if guarded {
+		total := ps + innerSize + ps
+		region, err = rawMmap(total)
+		if err != nil {
+			return nil, err
+		}
+		if err := protectNone(region[:ps]); err != nil {
+			rawMunmap(region)
+			return nil, err
+		}
+		if err := protectNone(region[ps+innerSize:]); err != nil {
+			rawMunmap(region)
+			return nil, err
+		}
+		lock = region[ps : ps+innerSize]
+	} else {
+		region, err = rawMmap(innerSize)
+		if err != nil {
+			return nil, err
+		}
+		lock = region
+	}
+
+	if err := lockRegion(lock); err != nil {
+		rawMunmap(region)
+		return nil, err
+	}
+	hardenRegion(lock)
+
+	buf := &Buffer{
+		data:    lock[:size],
+		lock:    lock,
+		region:  region,
+		guarded: guarded,
+	}
+
+	if cfg.mode&GuardCanary != 0 {
+		if _, err := rand.Read(buf.canaryWant[:]); err != nil {
+			buf.Close()
+			return nil, fmt.Errorf("securemem: generating canary: %w", err)
+		}
+		copy(lock[size:size+8], buf.canaryWant[:])
+		buf.hasCanary = true
+		buf.canaryOff = size
+	}
+
+	if cfg.finalizer {
+		runtime.SetFinalizer(buf, func(b *Buffer) { b.Close() })
+	}
+
+	return buf, nil
+}
+
+// VerifyCanary reports ErrCanaryCorrupted if the buffer's canary word (see
+// GuardCanary) no longer matches the value written when it was allocated.
+// It returns nil if the buffer has no canary, or is closed.
+func (b *Buffer) VerifyCanary() error {
+	if !b.hasCanary || b.closed {
+		return nil
+	}
+	got := b.lock[b.canaryOff : b.canaryOff+8]
+	if !bytes.Equal(got, b.canaryWant[:]) {
+		return ErrCanaryCorrupted
+	}
+	return nil
+}
+
+func roundUp(n, multiple int) int {
+	if multiple <= 0 || n%multiple == 0 {
+		return n
+	}
+	return n + multiple - n%multiple
+}
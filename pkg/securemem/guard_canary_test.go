@@ -0,0 +1,48 @@
+package securemem
+
+import "testing"
+
+func TestNewGuardedCanaryOnly(t *testing.T) {
+	buf, err := NewGuarded(24, WithGuardMode(GuardCanary))
+	if err != nil {
+		t.Fatalf("NewGuarded: %v", err)
+	}
+	if len(buf.Bytes()) != 24 {
+		t.Fatalf("Bytes() length = %d, want 24", len(buf.Bytes()))
+	}
+	if err := buf.VerifyCanary(); err != nil {
+		t.Fatalf("VerifyCanary on untouched buffer: %v", err)
+	}
+	if err := buf.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestNewGuardedDetectsCanaryCorruption(t *testing.T) {
+	buf, err := NewGuarded(16, WithGuardMode(GuardCanary))
+	if err != nil {
+		t.Fatalf("NewGuarded: %v", err)
+	}
+
+	// Simulate a linear overflow that writes one byte past the usable
+	// region into the canary.
+	buf.lock[buf.canaryOff] ^= 0xff
+
+	if err := buf.VerifyCanary(); err != ErrCanaryCorrupted {
+		t.Fatalf("VerifyCanary = %v, want ErrCanaryCorrupted", err)
+	}
+	if err := buf.Close(); err != ErrCanaryCorrupted {
+		t.Fatalf("Close = %v, want ErrCanaryCorrupted", err)
+	}
+}
+
+func TestNewGuardedBoth(t *testing.T) {
+	buf, err := NewGuarded(32)
+	if err != nil {
+		t.Fatalf("NewGuarded: %v", err)
+	}
+	defer buf.Close()
+	if !buf.guarded || !buf.hasCanary {
+		t.Fatalf("default GuardBoth should enable both guard pages and a canary")
+	}
+}
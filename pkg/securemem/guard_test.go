@@ -0,0 +1,56 @@
+package securemem
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"testing"
+	"unsafe"
+)
+
+const crashHelperEnv = "SECUREMEM_RUN_CRASH_HELPER"
+
+// TestOverflowFaultsIntoGuardPage re-execs this test binary, which (via
+// runCrashHelper below) allocates a guarded Buffer and deliberately writes
+// past the end of it into the tail guard page. The parent process asserts
+// the child died from a guard-page fault rather than exiting cleanly.
+func TestOverflowFaultsIntoGuardPage(t *testing.T) {
+	if os.Getenv(crashHelperEnv) == "1" {
+		runCrashHelper()
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestOverflowFaultsIntoGuardPage")
+	cmd.Env = append(os.Environ(), crashHelperEnv+"=1")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected helper process to crash, it exited cleanly; output: %s", out)
+	}
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Fatalf("expected *exec.ExitError, got %T: %v (output: %s)", err, err, out)
+	}
+	// The Go runtime intercepts the hardware fault itself before the OS
+	// would otherwise kill the process with SIGSEGV/SIGBUS, and reports
+	// it via a "fatal error: fault" crash dump rather than letting the
+	// signal terminate the process visibly to the parent.
+	if !bytes.Contains(out, []byte("SIGSEGV")) && !bytes.Contains(out, []byte("SIGBUS")) {
+		t.Fatalf("helper process exited but not from a guard-page fault: %v (output: %s)", err, out)
+	}
+}
+
+// runCrashHelper runs in the re-exec'd child process. It must never return
+// normally: either the write below faults the process, or something is
+// wrong with guard page setup and the test should fail loudly.
+func runCrashHelper() {
+	buf, err := NewGuarded(16, WithGuardMode(GuardHard))
+	if err != nil {
+		os.Exit(2)
+	}
+
+	// buf.region is pageSize (head guard) + innerSize + pageSize (tail
+	// guard); write to the first byte of the tail guard page.
+	tail := unsafe.Pointer(&buf.region[len(buf.region)-pageSize()])
+	*(*byte)(tail) = 1
+
+	os.Exit(0) // should be unreachable
+}
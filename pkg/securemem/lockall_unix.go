@@ -0,0 +1,32 @@
+//go:build linux || darwin
+
+package securemem
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// LockallFlag selects mlockall's behavior; values mirror MCL_CURRENT and
+// MCL_FUTURE.
+type LockallFlag int
+
+const (
+	// LockCurrent locks all pages currently mapped into the process.
+	LockCurrent LockallFlag = syscall.MCL_CURRENT
+	// LockFuture locks all pages mapped into the process in the future.
+	LockFuture LockallFlag = syscall.MCL_FUTURE
+)
+
+// Lockall locks the calling process's entire address space (or, with
+// LockFuture, all future mappings too) so that none of it is ever swapped
+// out. It is a coarser alternative to locking individual Buffers.
+func Lockall(flags LockallFlag) error {
+	if err := syscall.Mlockall(int(flags)); err != nil {
+		if err == syscall.ENOMEM || err == syscall.EPERM {
+			return &MemlockLimitError{Err: err}
+		}
+		return fmt.Errorf("securemem: mlockall: %w", err)
+	}
+	return nil
+}
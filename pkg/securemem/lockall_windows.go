@@ -0,0 +1,18 @@
+//go:build windows
+
+package securemem
+
+// LockallFlag selects mlockall-equivalent behavior. Windows has no
+// process-wide lock-everything primitive, so Lockall always returns
+// ErrNotSupported; lock individual Buffers instead.
+type LockallFlag int
+
+const (
+	LockCurrent LockallFlag = iota
+	LockFuture
+)
+
+// Lockall is not supported on Windows; see LockallFlag.
+func Lockall(flags LockallFlag) error {
+	return ErrNotSupported
+}
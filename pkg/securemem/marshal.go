@@ -0,0 +1,50 @@
+package securemem
+
+import (
+	"fmt"
+
+	"github.com/ursa-mikail/mem_safe_copy_transfer/pkg/safecopy"
+	"github.com/ursa-mikail/mem_safe_copy_transfer/pkg/secmarshal"
+)
+
+// PutAt encodes m into the buffer at byte offset off. The write goes
+// through pkg/safecopy, so a Buffer whose mapping has become invalid (a
+// guard-page hit) returns an error instead of crashing the process. For a
+// Marshallable with Packed() true, this is a single memmove with no
+// intermediate allocation: m.MarshalUnsafe writes directly into the
+// buffer's own backing slice.
+//
+// PutAt does not synchronize with a concurrent Close on the same Buffer;
+// see the concurrency note on Copy.
+//
+// If SetPointerCheck is enabled, PutAt also scans the bytes it just wrote
+// (and, at PointerCheckFull, b's whole locked region) for values that look
+// like live Go pointers, returning ErrGoPointerLeak.
+func (b *Buffer) PutAt(off int, m secmarshal.Marshallable) error {
+	if b.closed {
+		return ErrClosed
+	}
+	size := m.SizeBytes()
+	if off < 0 || size < 0 || off+size > len(b.data) {
+		return fmt.Errorf("securemem: PutAt(off=%d, size=%d) out of range for %d-byte buffer", off, size, len(b.data))
+	}
+	dst := b.data[off : off+size]
+	if err := safecopy.Try(func() { m.MarshalUnsafe(dst) }); err != nil {
+		return err
+	}
+	return checkPointerWrite(b, dst)
+}
+
+// GetAt decodes the buffer's bytes at offset off into m. See PutAt for the
+// fault-handling and allocation behavior.
+func (b *Buffer) GetAt(off int, m secmarshal.Marshallable) error {
+	if b.closed {
+		return ErrClosed
+	}
+	size := m.SizeBytes()
+	if off < 0 || size < 0 || off+size > len(b.data) {
+		return fmt.Errorf("securemem: GetAt(off=%d, size=%d) out of range for %d-byte buffer", off, size, len(b.data))
+	}
+	src := b.data[off : off+size]
+	return safecopy.Try(func() { m.UnmarshalUnsafe(src) })
+}
@@ -0,0 +1,141 @@
+package securemem
+
+import (
+	"errors"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ErrGoPointerLeak is returned by Copy and PutAt, when pointer checking is
+// enabled (see SetPointerCheck), if the bytes just written into a locked
+// buffer look like a live Go pointer. Locked buffers are mapped with
+// MAP_ANON outside the Go heap, so the garbage collector never scans
+// them; a Go pointer hidden in one can be collected while still
+// referenced, or simply never traced, and the program eventually crashes
+// far from the code that caused it.
+var ErrGoPointerLeak = errors.New("securemem: value written into locked buffer looks like a live Go pointer")
+
+// PointerCheckLevel selects how much of a Copy/PutAt call SetPointerCheck
+// scans for Go pointers, mirroring the levels of GODEBUG=cgocheck.
+type PointerCheckLevel int
+
+const (
+	// PointerCheckOff disables the check. This is the default.
+	PointerCheckOff PointerCheckLevel = 0
+	// PointerCheckAPIBoundary scans only the bytes passed to the current
+	// Copy or PutAt call.
+	PointerCheckAPIBoundary PointerCheckLevel = 1
+	// PointerCheckFull additionally rescans the destination buffer's
+	// entire locked region on every call.
+	PointerCheckFull PointerCheckLevel = 2
+)
+
+var pointerCheckLevel int32
+
+// SetPointerCheck sets the process-wide pointer-check level applied by
+// Copy and Buffer.PutAt. It is off by default; turning it on adds
+// an O(size) scan to every call, so it is meant for testing and
+// debugging a caller suspected of smuggling Go pointers into locked
+// memory, not for routine production use.
+func SetPointerCheck(level PointerCheckLevel) {
+	atomic.StoreInt32(&pointerCheckLevel, int32(level))
+}
+
+func currentPointerCheckLevel() PointerCheckLevel {
+	return PointerCheckLevel(atomic.LoadInt32(&pointerCheckLevel))
+}
+
+// runtime_spanOfHeap reports the heap span containing p, or nil if p
+// doesn't point into the heap. go:linkname pulls in an unexported runtime
+// function; there is no supported public API for "does this bit pattern
+// look like a pointer into our heap", which is exactly the question
+// GODEBUG=cgocheck answers the same way internally.
+//
+//go:linkname runtime_spanOfHeap runtime.spanOfHeap
+func runtime_spanOfHeap(p uintptr) unsafe.Pointer
+
+// moduledata mirrors the leading fields of runtime.moduledata -- only as
+// far as the data/bss/noptrdata/noptrbss bounds this package needs.
+// go:linkname requires the field layout up to that point to match the
+// runtime's exactly; trailing fields (ftab's element type, everything
+// after enoptrbss) are deliberately left out since nothing here reads
+// them. This is tied to the runtime internals of the Go version this
+// module is built with, same as runtime_spanOfHeap above.
+type moduledata struct {
+	pcHeader     uintptr
+	funcnametab  []byte
+	cutab        []uint32
+	filetab      []byte
+	pctab        []byte
+	pclntable    []byte
+	ftab         []uintptr
+	findfunctab  uintptr
+	minpc, maxpc uintptr
+
+	text, etext           uintptr
+	noptrdata, enoptrdata uintptr
+	data, edata           uintptr
+	bss, ebss             uintptr
+	noptrbss, enoptrbss   uintptr
+}
+
+//go:linkname firstmoduledata runtime.firstmoduledata
+var firstmoduledata moduledata
+
+func looksLikeGoPointer(word uintptr) bool {
+	if word == 0 {
+		return false
+	}
+	if runtime_spanOfHeap(word) != nil {
+		return true
+	}
+	// Globals and package-level statics never land in a heap span, but
+	// GODEBUG=cgocheck still flags them -- they're live Go pointers too,
+	// just ones the GC finds by scanning the data/bss sections instead
+	// of a span. Check those ranges directly off firstmoduledata. A
+	// pointer-free global like a plain [8]byte is linked into
+	// noptrdata/noptrbss rather than data/bss, so both pairs need
+	// checking.
+	md := &firstmoduledata
+	if word >= md.data && word < md.edata {
+		return true
+	}
+	if word >= md.noptrdata && word < md.enoptrdata {
+		return true
+	}
+	if word >= md.bss && word < md.ebss {
+		return true
+	}
+	if word >= md.noptrbss && word < md.enoptrbss {
+		return true
+	}
+	return false
+}
+
+// scanForGoPointers checks b, 8 bytes at a time, for values whose bit
+// pattern looks like a live Go pointer.
+func scanForGoPointers(b []byte) error {
+	for i := 0; i+8 <= len(b); i += 8 {
+		word := *(*uintptr)(unsafe.Pointer(&b[i]))
+		if looksLikeGoPointer(word) {
+			return ErrGoPointerLeak
+		}
+	}
+	return nil
+}
+
+// checkPointerWrite applies the current SetPointerCheck level after
+// written has just been written into dst.
+func checkPointerWrite(dst *Buffer, written []byte) error {
+	switch currentPointerCheckLevel() {
+	case PointerCheckOff:
+		return nil
+	case PointerCheckFull:
+		if err := scanForGoPointers(dst.lock); err != nil {
+			return err
+		}
+		return nil
+	default: // PointerCheckAPIBoundary and any other nonzero value
+		return scanForGoPointers(written)
+	}
+}
@@ -0,0 +1,119 @@
+package securemem
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+)
+
+// leaked is a heap-allocated object whose address we smuggle into a locked
+// buffer below. It's a package var (rather than a local) so it can't be
+// proven unreachable and collected out from under the test.
+var leaked = new(int)
+
+// globalSecret is never heap-allocated; its address lives in the data/bss
+// section of the binary. looksLikeGoPointer must catch this case too, not
+// just heap spans -- GODEBUG=cgocheck does the same.
+var globalSecret [8]byte
+
+func writePointerBytes(dst []byte, p *int) {
+	*(*uintptr)(unsafe.Pointer(&dst[0])) = uintptr(unsafe.Pointer(p))
+}
+
+// rawBytes is a Marshallable that copies its own bytes verbatim, used to
+// drive PutAt with attacker-controlled content in these tests.
+type rawBytes []byte
+
+func (r rawBytes) SizeBytes() int             { return len(r) }
+func (r rawBytes) MarshalUnsafe(dst []byte)   { copy(dst, r) }
+func (r rawBytes) UnmarshalUnsafe(src []byte) { copy(r, src) }
+func (r rawBytes) Packed() bool               { return true }
+
+func TestCopyDetectsGoPointerLeak(t *testing.T) {
+	defer SetPointerCheck(PointerCheckOff)
+
+	src, err := New(8)
+	if err != nil {
+		t.Fatalf("New(src): %v", err)
+	}
+	defer src.Close()
+	dst, err := New(8)
+	if err != nil {
+		t.Fatalf("New(dst): %v", err)
+	}
+	defer dst.Close()
+
+	writePointerBytes(src.Bytes(), leaked)
+
+	SetPointerCheck(PointerCheckOff)
+	if _, err := Copy(dst, src); err != nil {
+		t.Fatalf("Copy with pointer check off: %v", err)
+	}
+
+	SetPointerCheck(PointerCheckAPIBoundary)
+	if _, err := Copy(dst, src); !errors.Is(err, ErrGoPointerLeak) {
+		t.Fatalf("Copy with pointer check on: got %v, want ErrGoPointerLeak", err)
+	}
+}
+
+func TestPutAtDetectsGoPointerLeak(t *testing.T) {
+	defer SetPointerCheck(PointerCheckOff)
+
+	buf, err := New(16)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer buf.Close()
+
+	ptrBytes := make([]byte, 8)
+	writePointerBytes(ptrBytes, leaked)
+
+	SetPointerCheck(PointerCheckAPIBoundary)
+	if err := buf.PutAt(0, rawBytes(ptrBytes)); !errors.Is(err, ErrGoPointerLeak) {
+		t.Fatalf("PutAt with pointer check on: got %v, want ErrGoPointerLeak", err)
+	}
+}
+
+func TestCopyDetectsGoPointerLeakFromGlobal(t *testing.T) {
+	defer SetPointerCheck(PointerCheckOff)
+
+	src, err := New(8)
+	if err != nil {
+		t.Fatalf("New(src): %v", err)
+	}
+	defer src.Close()
+	dst, err := New(8)
+	if err != nil {
+		t.Fatalf("New(dst): %v", err)
+	}
+	defer dst.Close()
+
+	writePointerBytes(src.Bytes(), (*int)(unsafe.Pointer(&globalSecret[0])))
+
+	SetPointerCheck(PointerCheckAPIBoundary)
+	if _, err := Copy(dst, src); !errors.Is(err, ErrGoPointerLeak) {
+		t.Fatalf("Copy of a pointer to a package-level global: got %v, want ErrGoPointerLeak", err)
+	}
+}
+
+func TestPointerCheckFullRescansBuffer(t *testing.T) {
+	defer SetPointerCheck(PointerCheckOff)
+
+	buf, err := New(16)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer buf.Close()
+
+	writePointerBytes(buf.Bytes()[8:], leaked)
+
+	SetPointerCheck(PointerCheckAPIBoundary)
+	if err := buf.PutAt(0, rawBytes(make([]byte, 8))); err != nil {
+		t.Fatalf("PutAt at API-boundary level should not see the already-leaked tail: %v", err)
+	}
+
+	SetPointerCheck(PointerCheckFull)
+	if err := buf.PutAt(0, rawBytes(make([]byte, 8))); !errors.Is(err, ErrGoPointerLeak) {
+		t.Fatalf("PutAt at full-scan level: got %v, want ErrGoPointerLeak", err)
+	}
+}
@@ -0,0 +1,69 @@
+// Package wipe provides a compiler-proof primitive for zeroing secret
+// bytes. A hand-written `for i := range b { b[i] = 0 }` loop is at the
+// mercy of the compiler's dead-store elimination once b becomes
+// unreachable (e.g. right before the caller drops its last reference) --
+// nothing obliges the compiler to keep a write that nothing reads back.
+// Wipe goes through the runtime's own memclr, plus runtime.KeepAlive and
+// an unconditional, noinline store of the cleared address, so the clear
+// cannot be proven dead and elided.
+package wipe
+
+import (
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+)
+
+//go:linkname memclrNoHeapPointers runtime.memclrNoHeapPointers
+func memclrNoHeapPointers(ptr unsafe.Pointer, n uintptr)
+
+// sink is written to by observe below. Nothing ever reads it; its only
+// purpose is to give the compiler a visible use of the cleared address
+// that it cannot optimize away, since atomic stores are never dead-code
+// eliminated.
+var sink unsafe.Pointer
+
+//go:noinline
+func observe(p unsafe.Pointer) {
+	atomic.StorePointer(&sink, p)
+}
+
+// Wipe overwrites b with zeroes. It is safe to call with a nil or empty
+// slice.
+func Wipe(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	ptr := unsafe.Pointer(&b[0])
+	memclrNoHeapPointers(ptr, uintptr(len(b)))
+	observe(ptr)
+	runtime.KeepAlive(b)
+}
+
+// WipeString overwrites the bytes backing *s with zeroes and resets *s to
+// the empty string. Go strings are meant to be immutable; only call this
+// on strings built specifically to hold secret data (never on constants
+// or anything that might share backing storage via substring/concat
+// optimizations), since mutating shared backing storage would corrupt
+// whatever else points at it.
+func WipeString(s *string) {
+	if s == nil || len(*s) == 0 {
+		return
+	}
+	data := unsafe.StringData(*s)
+	b := unsafe.Slice(data, len(*s))
+	memclrNoHeapPointers(unsafe.Pointer(&b[0]), uintptr(len(b)))
+	observe(unsafe.Pointer(&b[0]))
+	runtime.KeepAlive(s)
+	*s = ""
+}
+
+// WipeStruct overwrites size bytes at p with zeroes, for callers holding a
+// typed struct (e.g. via secmarshal) rather than a []byte.
+func WipeStruct(p unsafe.Pointer, size int) {
+	if p == nil || size <= 0 {
+		return
+	}
+	memclrNoHeapPointers(p, uintptr(size))
+	observe(p)
+}
@@ -0,0 +1,56 @@
+package wipe
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestWipe(t *testing.T) {
+	b := []byte("0123456789abcdef")
+	Wipe(b)
+	for i, c := range b {
+		if c != 0 {
+			t.Fatalf("byte %d = %#x after Wipe, want 0", i, c)
+		}
+	}
+}
+
+func TestWipeEmpty(t *testing.T) {
+	Wipe(nil)
+	Wipe([]byte{})
+}
+
+func TestWipeString(t *testing.T) {
+	s := string([]byte("top secret password"))
+	WipeString(&s)
+	if s != "" {
+		t.Fatalf("s = %q after WipeString, want empty", s)
+	}
+}
+
+func TestWipeStringNilAndEmpty(t *testing.T) {
+	WipeString(nil)
+	empty := ""
+	WipeString(&empty)
+}
+
+func TestWipeStruct(t *testing.T) {
+	type secret struct {
+		a uint64
+		b [8]byte
+	}
+	s := secret{a: 0xdeadbeef}
+	copy(s.b[:], "password")
+
+	WipeStruct(unsafe.Pointer(&s), int(unsafe.Sizeof(s)))
+
+	if s != (secret{}) {
+		t.Fatalf("s = %+v after WipeStruct, want zero value", s)
+	}
+}
+
+func TestWipeStructNilAndZero(t *testing.T) {
+	WipeStruct(nil, 8)
+	var x byte
+	WipeStruct(unsafe.Pointer(&x), 0)
+}